@@ -0,0 +1,258 @@
+// Package acoustid is a client for the AcoustID web service
+// (https://acoustid.org/webservice), so callers can look up and submit
+// fingerprints produced by gochroma without hand-crafting HTTP calls.
+package acoustid
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-fingerprint/gochroma"
+)
+
+// Default AcoustID web service endpoints.
+const (
+	LookupURL = "https://api.acoustid.org/v2/lookup"
+	SubmitURL = "https://api.acoustid.org/v2/submit"
+)
+
+const defaultMaxRetries = 3
+
+// Common meta values accepted by the lookup endpoint's meta parameter.
+const (
+	MetaRecordings    = "recordings"
+	MetaReleaseGroups = "releasegroups"
+	MetaReleases      = "releases"
+	MetaTracks        = "tracks"
+	MetaCompress      = "compress"
+	MetaUsermeta      = "usermeta"
+)
+
+// A Client talks to the AcoustID web service.
+type Client struct {
+	// APIKey authenticates lookup and submission requests.
+	APIKey string
+	// LookupURL overrides LookupURL, mainly for tests.
+	LookupURL string
+	// SubmitURL overrides SubmitURL, mainly for tests.
+	SubmitURL string
+	// HTTPClient is the client used for requests. A nil value uses
+	// http.DefaultClient.
+	HTTPClient *http.Client
+	// MaxRetries caps the number of retries on a 5xx response. Zero uses
+	// defaultMaxRetries.
+	MaxRetries int
+}
+
+// New creates a Client authenticated with apiKey.
+func New(apiKey string) *Client {
+	return &Client{APIKey: apiKey}
+}
+
+// Meta selects which related data a Lookup call returns, mirroring
+// AcoustID's "meta=recordings+releasegroups+compress" query parameter.
+type Meta []string
+
+func (m Meta) String() string {
+	return strings.Join([]string(m), "+")
+}
+
+// Artist is a MusicBrainz artist credited on a Recording.
+type Artist struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ReleaseGroup is a MusicBrainz release group a Recording appears on.
+type ReleaseGroup struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Recording is one MusicBrainz recording matched by a lookup.
+type Recording struct {
+	ID            string         `json:"id"`
+	Title         string         `json:"title,omitempty"`
+	Artists       []Artist       `json:"artists,omitempty"`
+	ReleaseGroups []ReleaseGroup `json:"releasegroups,omitempty"`
+}
+
+// LookupResult is one fingerprint match returned by Lookup.
+type LookupResult struct {
+	ID         string      `json:"id"`
+	Score      float64     `json:"score"`
+	Recordings []Recording `json:"recordings,omitempty"`
+}
+
+type apiError struct {
+	Message string `json:"message"`
+}
+
+type lookupResponse struct {
+	Status  string         `json:"status"`
+	Results []LookupResult `json:"results"`
+	Error   *apiError      `json:"error,omitempty"`
+}
+
+// Lookup submits a fingerprint for identification and returns the
+// matched recordings, ordered by descending score.
+func (c *Client) Lookup(result gochroma.Result, meta Meta) ([]LookupResult, error) {
+	values := url.Values{
+		"client":      {c.APIKey},
+		"duration":    {strconv.Itoa(int(math.Round(result.Duration)))},
+		"fingerprint": {result.Fingerprint},
+		"format":      {"json"},
+	}
+	if len(meta) > 0 {
+		values.Set("meta", meta.String())
+	}
+
+	body, err := c.do(c.lookupURL(), values)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp lookupResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "ok" {
+		return nil, apiErr(resp.Status, resp.Error)
+	}
+	sort.SliceStable(resp.Results, func(i, j int) bool {
+		return resp.Results[i].Score > resp.Results[j].Score
+	})
+	return resp.Results, nil
+}
+
+// Submission is one fingerprint to contribute via Submit.
+type Submission struct {
+	Result gochroma.Result
+	// MBID optionally associates the submission with a known
+	// MusicBrainz recording.
+	MBID string
+}
+
+type submitResponse struct {
+	Status string    `json:"status"`
+	Error  *apiError `json:"error,omitempty"`
+}
+
+// Submit contributes new fingerprints to AcoustID.
+func (c *Client) Submit(subs []Submission) error {
+	values := url.Values{"client": {c.APIKey}, "format": {"json"}}
+	for i, s := range subs {
+		idx := strconv.Itoa(i)
+		values.Set("duration."+idx, strconv.Itoa(int(math.Round(s.Result.Duration))))
+		values.Set("fingerprint."+idx, s.Result.Fingerprint)
+		if s.MBID != "" {
+			values.Set("mbid."+idx, s.MBID)
+		}
+	}
+
+	body, err := c.do(c.submitURL(), values)
+	if err != nil {
+		return err
+	}
+
+	var resp submitResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+	if resp.Status != "ok" {
+		return apiErr(resp.Status, resp.Error)
+	}
+	return nil
+}
+
+func apiErr(status string, e *apiError) error {
+	if e != nil {
+		return fmt.Errorf("acoustid: %s", e.Message)
+	}
+	return fmt.Errorf("acoustid: request failed with status %q", status)
+}
+
+func (c *Client) lookupURL() string {
+	if c.LookupURL != "" {
+		return c.LookupURL
+	}
+	return LookupURL
+}
+
+func (c *Client) submitURL() string {
+	if c.SubmitURL != "" {
+		return c.SubmitURL
+	}
+	return SubmitURL
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// do POSTs values, gzip-compressed, to target, retrying on 5xx responses
+// with exponential backoff.
+func (c *Client) do(target string, values url.Values) ([]byte, error) {
+	maxRetries := c.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(values.Encode())); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	payload := buf.Bytes()
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<uint(attempt-1)) * time.Second)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Content-Encoding", "gzip")
+
+		resp, err := c.httpClient().Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("acoustid: server error: %s", resp.Status)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("acoustid: unexpected status: %s", resp.Status)
+		}
+		return body, nil
+	}
+	return nil, lastErr
+}
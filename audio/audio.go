@@ -0,0 +1,129 @@
+// Package audio decodes compressed audio into the raw PCM gochroma.Printer
+// expects, auto-detecting format/rate/channels the way fpcalc does before
+// fingerprinting.
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// Default output format: chromaprint's own algorithms are tuned for mono
+// audio at this sample rate.
+const (
+	DefaultRate     = 11025
+	DefaultChannels = 1
+)
+
+// A Decoder turns an arbitrary audio stream into 16-bit signed
+// little-endian PCM, reporting the sample rate and channel count it
+// decoded to.
+//
+// The returned pcm may hold a background resource, such as a decoder
+// subprocess, that outlives the read if the caller stops before EOF
+// (e.g. because it only fingerprints the first N seconds of a longer
+// stream). Callers should type-assert pcm to io.Closer and Close it
+// once they're done, whether or not they read it to EOF.
+type Decoder interface {
+	Decode(src io.Reader) (rate, channels int, pcm io.Reader, err error)
+}
+
+// FfmpegDecoder decodes audio by shelling out to the ffmpeg binary,
+// matching fpcalc's own approach to format auto-detection. It requires
+// ffmpeg to be installed and on $PATH.
+type FfmpegDecoder struct {
+	// Rate is the sample rate to resample to. Zero uses DefaultRate.
+	Rate int
+	// Channels is the channel count to resample to. Zero uses DefaultChannels.
+	Channels int
+	// Path is the ffmpeg binary to run. Empty uses "ffmpeg".
+	Path string
+}
+
+// Decode runs src through ffmpeg, resampling to d.Rate/d.Channels (or the
+// package defaults) and returning raw S16LE PCM. The returned pcm
+// implements io.Closer; callers must Close it once done, even if they
+// didn't read it to EOF, or the ffmpeg process leaks.
+func (d FfmpegDecoder) Decode(src io.Reader) (rate, channels int, pcm io.Reader, err error) {
+	rate = d.Rate
+	if rate == 0 {
+		rate = DefaultRate
+	}
+	channels = d.Channels
+	if channels == 0 {
+		channels = DefaultChannels
+	}
+	path := d.Path
+	if path == "" {
+		path = "ffmpeg"
+	}
+
+	cmd := exec.Command(path,
+		"-i", "pipe:0",
+		"-f", "s16le",
+		"-ar", fmt.Sprint(rate),
+		"-ac", fmt.Sprint(channels),
+		"pipe:1",
+	)
+	cmd.Stdin = src
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, 0, nil, err
+	}
+
+	return rate, channels, &cmdReader{ReadCloser: out, cmd: cmd, stderr: &stderr}, nil
+}
+
+// cmdReader waits on the ffmpeg process once its stdout is fully
+// consumed, surfacing a decode error with ffmpeg's stderr output if it
+// exited uncleanly. If the caller stops reading before EOF, Close kills
+// the process instead, so it's never left blocked writing to a full
+// stdout pipe.
+type cmdReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+
+	waitOnce sync.Once
+	waitErr  error
+}
+
+func (r *cmdReader) wait() error {
+	r.waitOnce.Do(func() { r.waitErr = r.cmd.Wait() })
+	return r.waitErr
+}
+
+func (r *cmdReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err == io.EOF {
+		if werr := r.wait(); werr != nil {
+			return n, fmt.Errorf("audio: ffmpeg: %v: %s", werr, r.stderr.String())
+		}
+	}
+	return n, err
+}
+
+// Close terminates the ffmpeg process if it's still running and waits
+// for it to exit, reaping it even if it was never read to EOF.
+func (r *cmdReader) Close() error {
+	closeErr := r.ReadCloser.Close()
+	if r.cmd.Process != nil {
+		r.cmd.Process.Kill()
+	}
+	if err := r.wait(); err != nil && closeErr == nil {
+		// Wait returning an error here just means the Kill above worked;
+		// ffmpeg exiting on its own signal isn't worth reporting.
+		return nil
+	}
+	return closeErr
+}
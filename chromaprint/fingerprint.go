@@ -0,0 +1,115 @@
+package chromaprint
+
+// #include <stdlib.h>
+// #include <chromaprint.h>
+import "C"
+
+import (
+	"errors"
+	"math/bits"
+	"unsafe"
+)
+
+// Errors returned by EncodeFingerprint and DecodeFingerprint.
+var (
+	ErrEncodeFprint = errors.New("chromaprint: failed to encode fingerprint")
+	ErrDecodeFprint = errors.New("chromaprint: failed to decode fingerprint")
+	ErrFprintHash   = errors.New("chromaprint: failed to calculate fingerprint hash")
+)
+
+// EncodeFingerprint compresses a raw fingerprint for the given algorithm,
+// optionally base64-encoding the result the way AcoustID submissions
+// expect.
+func EncodeFingerprint(raw []int32, algorithm int, base64 bool) ([]byte, error) {
+	if len(raw) == 0 {
+		return nil, ErrEncodeFprint
+	}
+
+	var encoded unsafe.Pointer
+	var size C.int
+	var b64 C.int
+	if base64 {
+		b64 = 1
+	}
+
+	r := C.chromaprint_encode_fingerprint(
+		(*C.uint32_t)(unsafe.Pointer(&raw[0])), C.int(len(raw)),
+		C.int(algorithm), &encoded, &size, b64)
+	if int(r) < 1 {
+		return nil, ErrEncodeFprint
+	}
+	defer C.chromaprint_dealloc(encoded)
+
+	return C.GoBytes(encoded, size), nil
+}
+
+// DecodeFingerprint decodes a compressed fingerprint, as produced by
+// EncodeFingerprint or ChromaprintContext.GetFingerprint, back into raw
+// items.
+func DecodeFingerprint(data []byte, base64 bool) (raw []int32, algorithm int, err error) {
+	if len(data) == 0 {
+		return nil, 0, ErrDecodeFprint
+	}
+
+	var fp *C.uint32_t
+	var size, alg C.int
+	var b64 C.int
+	if base64 {
+		b64 = 1
+	}
+
+	r := C.chromaprint_decode_fingerprint(
+		unsafe.Pointer(&data[0]), C.int(len(data)), &fp, &size, &alg, b64)
+	if int(r) < 1 {
+		return nil, 0, ErrDecodeFprint
+	}
+	defer C.chromaprint_dealloc(unsafe.Pointer(fp))
+
+	raw = goInt32s(unsafe.Pointer(fp), int(size))
+	algorithm = int(alg)
+	return
+}
+
+// GetFingerprintHash returns a 32-bit SimHash of the fingerprint
+// calculated so far, suitable for fast approximate "same track?"
+// comparisons without storing the full fingerprint.
+func (ctx *ChromaprintContext) GetFingerprintHash() (uint32, error) {
+	var hash C.uint32_t
+	if int(C.chromaprint_get_fingerprint_hash(ctx.context, &hash)) < 1 {
+		return 0, ErrFprintHash
+	}
+	return uint32(hash), nil
+}
+
+// SimHash computes a 32-bit hash from raw fingerprint items by
+// majority-voting each bit position across all items: a bit is set in
+// the result if it's set in more items than not. This mirrors
+// ChromaprintContext.GetFingerprintHash for raw fingerprints obtained
+// some other way, e.g. via DecodeFingerprint.
+func SimHash(raw []int32) uint32 {
+	var counts [32]int
+	for _, item := range raw {
+		v := uint32(item)
+		for bit := 0; bit < 32; bit++ {
+			if v&(1<<uint(bit)) != 0 {
+				counts[bit]++
+			} else {
+				counts[bit]--
+			}
+		}
+	}
+
+	var hash uint32
+	for bit, count := range counts {
+		if count > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+	return hash
+}
+
+// HammingDistance returns the number of differing bits between two
+// hashes, as produced by SimHash or GetFingerprintHash.
+func HammingDistance(a, b uint32) int {
+	return bits.OnesCount32(a ^ b)
+}
@@ -0,0 +1,190 @@
+package gochroma
+
+import (
+	"io"
+	"time"
+
+	"github.com/go-fingerprint/fingerprint"
+)
+
+// overlapFilterSize mirrors the span, in item durations, that
+// libchromaprint's chroma filters look back from the current item.
+// Fingerprint items within this many item durations of a chunk boundary
+// may depend on samples that fall in the following chunk, so that much
+// audio is retained and replayed when overlap is requested.
+const overlapFilterSize = 7
+
+// A Chunk is one fixed-duration slice of a longer stream, as produced by
+// Printer.FingerprintChunks.
+type Chunk struct {
+	// StartTime is this chunk's offset from the start of the stream.
+	StartTime time.Duration
+	// Duration is the length of audio this chunk covers.
+	Duration time.Duration
+	// Fingerprint is the compressed fingerprint for this chunk.
+	Fingerprint string
+	// RawFingerprint is the uncompressed fingerprint for this chunk.
+	RawFingerprint []int32
+}
+
+// FingerprintChunks splits i into fixed-duration chunks of chunkSeconds
+// and returns one fingerprint per chunk with absolute start/end
+// timestamps, mirroring fpcalc's -chunk and -overlap flags. Unlike
+// Fingerprint and RawFingerprint, it ignores i.MaxSeconds so arbitrarily
+// long streams can be chunked.
+//
+// When overlap is true, the tail of each chunk is prepended to the next
+// one so that fingerprint items straddling a chunk boundary aren't lost.
+// This means consecutive chunks share that replayed audio: StartTime and
+// Duration describe the full span each chunk was fingerprinted over,
+// including the overlap, so chunk N+1's StartTime falls before chunk N's
+// StartTime+Duration rather than immediately after it.
+func (p *Printer) FingerprintChunks(i fingerprint.RawInfo, chunkSeconds float64, overlap bool) ([]Chunk, error) {
+	ctx := p.context
+	rate, channels := int(i.Rate), int(i.Channels)
+	bytesPerSample := 2 * channels
+
+	chunkBytes := int(chunkSeconds * float64(rate) * float64(bytesPerSample))
+	if chunkBytes <= 0 {
+		chunkBytes = seconds * rate * bytesPerSample
+	}
+
+	var overlapBytes int
+	if overlap {
+		overlapBytes = ctx.GetItemDurationSamples() * overlapFilterSize * bytesPerSample
+		if overlapBytes >= chunkBytes {
+			overlapBytes = 0
+		}
+	}
+
+	readBuf := make([]byte, seconds*rate*bytesPerSample)
+
+	var chunks []Chunk
+	var pending []byte
+	var eof bool
+	var offset time.Duration
+
+	for {
+		for !eof && len(pending) <= chunkBytes {
+			read, err := i.Src.Read(readBuf)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if read > 0 {
+				pending = append(pending, readBuf[:read]...)
+			}
+			if err == io.EOF || read == 0 {
+				eof = true
+			}
+		}
+
+		plan, ok := planChunk(pending, eof, chunkBytes, overlapBytes)
+		if !ok {
+			break
+		}
+
+		if err := ctx.Start(rate, channels); err != nil {
+			return nil, err
+		}
+		if err := ctx.Feed(plan.data); err != nil {
+			return nil, err
+		}
+		if err := ctx.Finish(); err != nil {
+			return nil, err
+		}
+
+		fprint, err := ctx.GetFingerprint()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := ctx.GetRawFingerprint()
+		if err != nil {
+			return nil, err
+		}
+
+		duration := bytesToDuration(len(plan.data), rate, bytesPerSample)
+		chunks = append(chunks, Chunk{
+			StartTime:      offset,
+			Duration:       duration,
+			Fingerprint:    fprint,
+			RawFingerprint: raw,
+		})
+		offset += duration
+
+		if plan.final {
+			break
+		}
+
+		offset -= bytesToDuration(plan.overlap, rate, bytesPerSample)
+		pending = plan.carry
+	}
+	return chunks, nil
+}
+
+// ChunkedResult is FingerprintChunks wrapped in a Result, ready for
+// JSON/text/plain serialization, mirroring fpcalc's -chunk -json output.
+// Result.Duration is left at zero, since chunks may overlap in time and
+// so have no single well-defined total duration; the per-chunk durations
+// in Result.Chunks carry that information instead.
+func (p *Printer) ChunkedResult(i fingerprint.RawInfo, chunkSeconds float64, overlap bool) (Result, error) {
+	chunks, err := p.FingerprintChunks(i, chunkSeconds, overlap)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Algorithm:  p.context.Algorithm(),
+		SampleRate: int(i.Rate),
+		Channels:   int(i.Channels),
+		Chunks:     chunks,
+	}, nil
+}
+
+// chunkPlan is the result of deciding how to slice the next chunk out of
+// buffered audio; see planChunk.
+type chunkPlan struct {
+	// data is the bytes to feed the fingerprinter for this chunk.
+	data []byte
+	// carry is the bytes to keep buffered for the next chunk, including
+	// any overlap tail.
+	carry []byte
+	// overlap is how many bytes at the front of carry were repeated from
+	// data, i.e. how far the next chunk's StartTime should roll back.
+	overlap int
+	// final reports whether this is the stream's last chunk.
+	final bool
+}
+
+// planChunk decides how much of pending to fingerprint as the next
+// chunk, given whether the source has been exhausted. It reports ok=false
+// when there isn't a chunk to emit yet: either pending is empty, or it
+// holds no more than chunkBytes and the source isn't at EOF, so the caller
+// should read more before calling again.
+func planChunk(pending []byte, eof bool, chunkBytes, overlapBytes int) (plan chunkPlan, ok bool) {
+	if len(pending) == 0 {
+		return chunkPlan{}, false
+	}
+	if len(pending) <= chunkBytes {
+		if !eof {
+			return chunkPlan{}, false
+		}
+		return chunkPlan{data: pending, final: true}, true
+	}
+
+	data := pending[:chunkBytes]
+	rest := pending[chunkBytes:]
+	if overlapBytes <= 0 {
+		return chunkPlan{data: data, carry: append([]byte{}, rest...)}, true
+	}
+
+	tail := data[len(data)-overlapBytes:]
+	carry := append(append([]byte{}, tail...), rest...)
+	return chunkPlan{data: data, carry: carry, overlap: len(tail)}, true
+}
+
+func bytesToDuration(n, rate, bytesPerSample int) time.Duration {
+	if rate <= 0 || bytesPerSample <= 0 {
+		return 0
+	}
+	samples := float64(n) / float64(bytesPerSample)
+	return time.Duration(samples / float64(rate) * float64(time.Second))
+}
@@ -0,0 +1,90 @@
+package gochroma
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanChunkWaitsForEOFAtExactBoundary(t *testing.T) {
+	pending := make([]byte, 100)
+
+	// Exactly chunkBytes buffered but the source isn't exhausted yet:
+	// there may be more audio to come, so no chunk should be emitted.
+	if _, ok := planChunk(pending, false, 100, 0); ok {
+		t.Fatal("planChunk emitted a chunk before EOF at an exact boundary")
+	}
+
+	// Same buffered bytes, but the source is now exhausted: this is the
+	// final (and only) chunk.
+	plan, ok := planChunk(pending, true, 100, 0)
+	if !ok {
+		t.Fatal("planChunk did not emit a chunk once EOF was reached")
+	}
+	if !plan.final {
+		t.Error("plan.final = false, want true")
+	}
+	if len(plan.data) != 100 {
+		t.Errorf("len(plan.data) = %d, want 100", len(plan.data))
+	}
+}
+
+func TestPlanChunkSplitsWithoutOverlap(t *testing.T) {
+	pending := make([]byte, 150)
+
+	plan, ok := planChunk(pending, false, 100, 0)
+	if !ok {
+		t.Fatal("planChunk did not emit a chunk with more than chunkBytes buffered")
+	}
+	if plan.final {
+		t.Error("plan.final = true, want false")
+	}
+	if len(plan.data) != 100 {
+		t.Errorf("len(plan.data) = %d, want 100", len(plan.data))
+	}
+	if len(plan.carry) != 50 {
+		t.Errorf("len(plan.carry) = %d, want 50", len(plan.carry))
+	}
+	if plan.overlap != 0 {
+		t.Errorf("plan.overlap = %d, want 0", plan.overlap)
+	}
+}
+
+func TestPlanChunkCarriesOverlapTail(t *testing.T) {
+	pending := make([]byte, 150)
+	for i := range pending {
+		pending[i] = byte(i)
+	}
+
+	plan, ok := planChunk(pending, false, 100, 20)
+	if !ok {
+		t.Fatal("planChunk did not emit a chunk with more than chunkBytes buffered")
+	}
+	if plan.overlap != 20 {
+		t.Errorf("plan.overlap = %d, want 20", plan.overlap)
+	}
+	// carry = last 20 bytes of data (80..99) followed by the unread rest (100..149).
+	if len(plan.carry) != 20+50 {
+		t.Errorf("len(plan.carry) = %d, want %d", len(plan.carry), 20+50)
+	}
+	if plan.carry[0] != 80 {
+		t.Errorf("plan.carry[0] = %d, want 80", plan.carry[0])
+	}
+	if plan.carry[len(plan.carry)-1] != pending[len(pending)-1] {
+		t.Error("plan.carry does not end with the unread tail of pending")
+	}
+}
+
+func TestPlanChunkReportsNoChunkForEmptyPending(t *testing.T) {
+	if _, ok := planChunk(nil, true, 100, 0); ok {
+		t.Error("planChunk emitted a chunk for empty pending")
+	}
+}
+
+func TestBytesToDuration(t *testing.T) {
+	// 1 second of mono S16LE audio at 1000Hz is 2000 bytes.
+	got := bytesToDuration(2000, 1000, 2)
+	want := time.Second
+	if got != want {
+		t.Errorf("bytesToDuration(2000, 1000, 2) = %v, want %v", got, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/go-fingerprint/fingerprint"
 	"github.com/go-fingerprint/gochroma/chromaprint"
 	"io"
+	"time"
 )
 
 const (
@@ -42,7 +43,7 @@ func (p *Printer) Close() {
 
 // Fingerprint implements fingerprint.Calculator interface.
 func (p *Printer) Fingerprint(i fingerprint.RawInfo) (fprint string, err error) {
-	if err = p.prepare(i); err != nil {
+	if _, err = p.prepare(i); err != nil {
 		return
 	}
 	fprint, err = p.context.GetFingerprint()
@@ -51,38 +52,73 @@ func (p *Printer) Fingerprint(i fingerprint.RawInfo) (fprint string, err error)
 
 // RawFingerprint implements fingerprint.Calculator interface.
 func (p *Printer) RawFingerprint(i fingerprint.RawInfo) (fprint []int32, err error) {
-	if err = p.prepare(i); err != nil {
+	if _, err = p.prepare(i); err != nil {
 		return
 	}
 	fprint, err = p.context.GetRawFingerprint()
 	return
 }
 
-func (p *Printer) prepare(i fingerprint.RawInfo) error {
+// FingerprintHash returns a 32-bit SimHash of i, for callers who only
+// need a fast, fixed-size hash for "same track?" comparisons rather
+// than the full fingerprint.
+func (p *Printer) FingerprintHash(i fingerprint.RawInfo) (hash uint32, err error) {
+	if _, err = p.prepare(i); err != nil {
+		return
+	}
+	hash, err = p.context.GetFingerprintHash()
+	return
+}
+
+// Result runs i through the calculator once and returns both the
+// compressed and raw fingerprint together with algorithm and format
+// metadata, ready for JSON/text/plain serialization.
+func (p *Printer) Result(i fingerprint.RawInfo) (Result, error) {
+	duration, err := p.prepare(i)
+	if err != nil {
+		return Result{}, err
+	}
+	fprint, err := p.context.GetFingerprint()
+	if err != nil {
+		return Result{}, err
+	}
+	raw, err := p.context.GetRawFingerprint()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Duration:       duration.Seconds(),
+		Fingerprint:    fprint,
+		RawFingerprint: raw,
+		Algorithm:      p.context.Algorithm(),
+		SampleRate:     int(i.Rate),
+		Channels:       int(i.Channels),
+	}, nil
+}
+
+// prepare feeds i into the context, via a Session, and returns the
+// duration of audio it actually processed.
+func (p *Printer) prepare(i fingerprint.RawInfo) (time.Duration, error) {
 	if i.MaxSeconds < minmaxseconds {
 		i.MaxSeconds = minmaxseconds
 	}
-	ctx := p.context
-	rate, channels := i.Rate, i.Channels
-	if err := ctx.Start(int(rate), int(channels)); err != nil {
-		return err
+	// i.Src may be backed by a decoder subprocess (see audio.Decoder); the
+	// MaxSeconds cap below means we often stop reading before EOF, so
+	// close it explicitly rather than relying on EOF to release it.
+	if c, ok := i.Src.(io.Closer); ok {
+		defer c.Close()
+	}
+	s := p.Session()
+	if err := s.Start(int(i.Rate), int(i.Channels)); err != nil {
+		return 0, err
 	}
-	numbytes := 2 * seconds * rate * channels
-	buf := make([]byte, numbytes)
-	for total := uint(0); total <= i.MaxSeconds; total += seconds {
-		read, err := i.Src.Read(buf)
-		if err != nil && err != io.EOF {
-			return err
-		}
-		if read == 0 {
-			break
-		}
-		if err := ctx.Feed(buf[:read]); err != nil {
-			return err
-		}
+	maxBytes := int64(i.MaxSeconds) * 2 * int64(i.Rate) * int64(i.Channels)
+	if _, err := io.Copy(s, io.LimitReader(i.Src, maxBytes)); err != nil {
+		return 0, err
 	}
-	if err := ctx.Finish(); err != nil {
-		return err
+	result, err := s.Close()
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	return time.Duration(result.Duration * float64(time.Second)), nil
 }
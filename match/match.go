@@ -0,0 +1,164 @@
+// Package match compares two raw chromaprint fingerprints and reports the
+// segments where they overlap, mirroring chromaprint's internal
+// FingerprintMatcher. It lets callers detect duplicate or overlapping
+// recordings without a second pass through libchromaprint.
+package match
+
+import (
+	"math/bits"
+	"time"
+)
+
+// DefaultThreshold is the maximum average per-item Hamming distance (out
+// of 32 bits) for a run of fingerprint items to be reported as a match.
+const DefaultThreshold = 10
+
+// minRunItems is the shortest run of matching items, at the chosen
+// offset, worth reporting.
+const minRunItems = 4
+
+// A Match describes one matching segment found between two
+// fingerprints.
+type Match struct {
+	// Offset1 is the item offset into the first fingerprint where the match starts.
+	Offset1 int
+	// Offset2 is the item offset into the second fingerprint where the match starts.
+	Offset2 int
+	// Duration is how long the matching segment lasts.
+	Duration time.Duration
+	// Score is the segment's similarity in [0,1], 1 being identical.
+	Score float64
+}
+
+// A Matcher compares raw fingerprints produced by the same algorithm.
+type Matcher struct {
+	// ItemDuration is the duration of one fingerprint item, as returned
+	// by chromaprint.ChromaprintContext.GetItemDuration.
+	ItemDuration time.Duration
+	// Threshold is the maximum average per-item Hamming distance allowed
+	// for a segment to be reported. Zero uses DefaultThreshold.
+	Threshold int
+}
+
+// New creates a Matcher for fingerprints whose items span itemDuration.
+func New(itemDuration time.Duration) *Matcher {
+	return &Matcher{ItemDuration: itemDuration, Threshold: DefaultThreshold}
+}
+
+// Compare finds the matching segments between two raw fingerprints. It
+// first finds the item offset of b relative to a with the lowest average
+// Hamming distance over their overlap, then walks the items along that
+// offset and reports contiguous runs in which every item's Hamming
+// distance is at most m.Threshold. Items are judged one at a time, not by
+// a windowed average, so a single item over the threshold ends a run.
+//
+// bestOffset scores every possible offset directly, an O(len(a)*(len(a)+
+// len(b))) scan; a coarse-to-fine search was tried first but its binned
+// histogram pre-filter was too noisy to reliably find the true offset for
+// short or non-bin-aligned fingerprints, so it was dropped in favor of
+// this simpler, exact search. Fingerprints from typical track lengths
+// (a few thousand items) are in practice cheap enough to compare this way.
+func (m *Matcher) Compare(a, b []int32) []Match {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+
+	threshold := m.Threshold
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	offset := bestOffset(a, b)
+	return m.refine(a, b, offset, threshold)
+}
+
+// bestOffset returns the item offset of b relative to a (such that
+// a[i] aligns with b[i-offset]) with the lowest average Hamming distance
+// over the two fingerprints' overlap. Offsets with no overlap are not
+// considered, so an offset can't win by default just because it compares
+// nothing.
+func bestOffset(a, b []int32) int {
+	var best int
+	var bestAvg float64
+	found := false
+
+	for off := -(len(b) - 1); off <= len(a)-1; off++ {
+		start := 0
+		if off > 0 {
+			start = off
+		}
+
+		var sum, count int
+		for i := start; i < len(a); i++ {
+			j := i - off
+			if j < 0 || j >= len(b) {
+				continue
+			}
+			sum += bits.OnesCount32(uint32(a[i] ^ b[j]))
+			count++
+		}
+		if count == 0 {
+			continue
+		}
+
+		avg := float64(sum) / float64(count)
+		if !found || avg < bestAvg {
+			found = true
+			bestAvg = avg
+			best = off
+		}
+	}
+	return best
+}
+
+// refine walks the a/b diagonal implied by offset and reports the
+// contiguous runs, at least minRunItems long, of items whose individual
+// Hamming distance is at most threshold.
+func (m *Matcher) refine(a, b []int32, offset, threshold int) []Match {
+	var matches []Match
+	var inRun bool
+	var runStartA, runStartB, runLen, runErrBits int
+
+	flush := func() {
+		if inRun && runLen >= minRunItems {
+			avg := float64(runErrBits) / float64(runLen*32)
+			matches = append(matches, Match{
+				Offset1:  runStartA,
+				Offset2:  runStartB,
+				Duration: time.Duration(runLen) * m.ItemDuration,
+				Score:    1 - avg,
+			})
+		}
+		inRun = false
+		runLen = 0
+		runErrBits = 0
+	}
+
+	start := 0
+	if offset > 0 {
+		start = offset
+	}
+	for i := start; i < len(a); i++ {
+		j := i - offset
+		if j < 0 || j >= len(b) {
+			flush()
+			continue
+		}
+
+		errBits := bits.OnesCount32(uint32(a[i] ^ b[j]))
+		if errBits <= threshold {
+			if !inRun {
+				inRun = true
+				runStartA, runStartB = i, j
+				runLen, runErrBits = 0, 0
+			}
+			runLen++
+			runErrBits += errBits
+		} else {
+			flush()
+		}
+	}
+	flush()
+
+	return matches
+}
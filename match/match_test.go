@@ -0,0 +1,95 @@
+package match
+
+import (
+	"testing"
+	"time"
+)
+
+// sequence deterministically generates n pseudo-random fingerprint items
+// from seed, without relying on math/rand, so tests stay reproducible.
+func sequence(seed uint32, n int) []int32 {
+	items := make([]int32, n)
+	state := seed
+	for i := range items {
+		state = state*1664525 + 1013904223
+		items[i] = int32(state)
+	}
+	return items
+}
+
+func TestCompareIdentical(t *testing.T) {
+	a := sequence(1, 600)
+	b := append([]int32{}, a...)
+
+	m := New(120 * time.Millisecond)
+	matches := m.Compare(a, b)
+	if len(matches) != 1 {
+		t.Fatalf("got %d matches, want 1: %+v", len(matches), matches)
+	}
+
+	got := matches[0]
+	if got.Offset1 != 0 || got.Offset2 != 0 {
+		t.Errorf("offsets = (%d, %d), want (0, 0)", got.Offset1, got.Offset2)
+	}
+	if got.Score < 0.99 {
+		t.Errorf("score = %f, want ~1", got.Score)
+	}
+}
+
+// TestCompareRecoversShift checks a straightforward round-number shift.
+func TestCompareRecoversShift(t *testing.T) {
+	const shift = 80
+
+	base := sequence(2, 400)
+	a := base
+	b := append(sequence(3, shift), base...)
+
+	m := New(120 * time.Millisecond)
+	matches := m.Compare(a, b)
+	if len(matches) == 0 {
+		t.Fatal("got no matches, want at least 1")
+	}
+
+	got := matches[0]
+	if delta := got.Offset2 - got.Offset1; delta != shift {
+		t.Errorf("Offset2-Offset1 = %d, want %d", delta, shift)
+	}
+	if got.Score < 0.99 {
+		t.Errorf("score = %f, want ~1", got.Score)
+	}
+}
+
+// TestCompareRecoversUnalignedShift uses a shift that, unlike 80, isn't a
+// multiple of 4, 8 or 16 items, so it'd catch a reintroduced coarse
+// pre-filter that only lands on offsets aligned to its bin size.
+func TestCompareRecoversUnalignedShift(t *testing.T) {
+	const shift = 150
+
+	base := sequence(2, 600)
+	a := base
+	b := append(sequence(3, shift), base...)
+
+	m := New(120 * time.Millisecond)
+	matches := m.Compare(a, b)
+	if len(matches) == 0 {
+		t.Fatal("got no matches, want at least 1")
+	}
+
+	got := matches[0]
+	if delta := got.Offset2 - got.Offset1; delta != shift {
+		t.Errorf("Offset2-Offset1 = %d, want %d", delta, shift)
+	}
+	if got.Score < 0.99 {
+		t.Errorf("score = %f, want ~1", got.Score)
+	}
+}
+
+func TestCompareNoOverlapYieldsNoMatch(t *testing.T) {
+	a := sequence(4, 100)
+	b := sequence(5, 100)
+
+	m := New(120 * time.Millisecond)
+	if matches := m.Compare(a, b); len(matches) != 0 {
+		t.Errorf("got %d matches for unrelated fingerprints, want 0: %+v", len(matches), matches)
+	}
+}
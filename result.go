@@ -0,0 +1,117 @@
+package gochroma
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// A Result carries everything needed to submit a fingerprint to
+// AcoustID: the fingerprint itself plus the metadata fpcalc prints
+// alongside it.
+type Result struct {
+	// Duration is the length of the fingerprinted audio, in seconds.
+	Duration float64
+	// Fingerprint is the compressed fingerprint.
+	Fingerprint string
+	// RawFingerprint is the uncompressed fingerprint, when available.
+	RawFingerprint []int32
+	// Algorithm is the fingerprinting algorithm used, one of the
+	// AlgorithmX constants.
+	Algorithm int
+	// SampleRate is the sample rate of the fingerprinted audio.
+	SampleRate int
+	// Channels is the channel count of the fingerprinted audio.
+	Channels int
+	// Chunks holds the per-chunk fingerprints when Result was built from
+	// a chunked fingerprinting pass; it's empty otherwise.
+	Chunks []Chunk
+}
+
+// resultJSON mirrors the shape acoustid.org expects for a submission:
+// the compressed fingerprint as a string, the raw fingerprint (if any)
+// as an array of unsigned 32-bit integers. When Result was built from a
+// chunked pass, Chunks mirrors fpcalc's -chunk -json array of per-chunk
+// fingerprints; duration and fingerprint are still emitted alongside it,
+// holding whatever Result has (zero and empty for ChunkedResult).
+type resultJSON struct {
+	Duration       float64     `json:"duration"`
+	Fingerprint    string      `json:"fingerprint"`
+	RawFingerprint []uint32    `json:"raw_fingerprint,omitempty"`
+	Chunks         []chunkJSON `json:"chunks,omitempty"`
+}
+
+// chunkJSON mirrors one entry of fpcalc's -chunk -json chunk array.
+type chunkJSON struct {
+	StartTime      float64  `json:"start_time"`
+	Duration       float64  `json:"duration"`
+	Fingerprint    string   `json:"fingerprint"`
+	RawFingerprint []uint32 `json:"raw_fingerprint,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, matching fpcalc's -json output
+// and acoustid.org's submission schema.
+func (r Result) MarshalJSON() ([]byte, error) {
+	rj := resultJSON{Duration: r.Duration, Fingerprint: r.Fingerprint}
+	if len(r.RawFingerprint) > 0 {
+		rj.RawFingerprint = rawToUint32(r.RawFingerprint)
+	}
+	if len(r.Chunks) > 0 {
+		rj.Chunks = make([]chunkJSON, len(r.Chunks))
+		for i, c := range r.Chunks {
+			rj.Chunks[i] = chunkJSON{
+				StartTime:      c.StartTime.Seconds(),
+				Duration:       c.Duration.Seconds(),
+				Fingerprint:    c.Fingerprint,
+				RawFingerprint: rawToUint32(c.RawFingerprint),
+			}
+		}
+	}
+	return json.Marshal(rj)
+}
+
+func rawToUint32(raw []int32) []uint32 {
+	if len(raw) == 0 {
+		return nil
+	}
+	out := make([]uint32, len(raw))
+	for i, v := range raw {
+		out[i] = uint32(v)
+	}
+	return out
+}
+
+// MarshalText implements encoding.TextMarshaler, matching fpcalc's
+// default TEXT output. For a chunked Result, it writes one
+// DURATION=/FINGERPRINT= stanza per chunk instead of one for the whole
+// stream, the way fpcalc -chunk does.
+func (r Result) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	if len(r.Chunks) > 0 {
+		for _, c := range r.Chunks {
+			fmt.Fprintf(&buf, "DURATION=%.0f\n", c.Duration.Seconds())
+			fmt.Fprintf(&buf, "FINGERPRINT=%s\n", c.Fingerprint)
+		}
+		return buf.Bytes(), nil
+	}
+	fmt.Fprintf(&buf, "DURATION=%.0f\n", r.Duration)
+	fmt.Fprintf(&buf, "FINGERPRINT=%s\n", r.Fingerprint)
+	return buf.Bytes(), nil
+}
+
+// WritePlain writes just the compressed fingerprint to w, matching
+// fpcalc's -plain output. For a chunked Result, it writes one
+// fingerprint per line, one per chunk.
+func (r Result) WritePlain(w io.Writer) error {
+	if len(r.Chunks) > 0 {
+		for _, c := range r.Chunks {
+			if _, err := fmt.Fprintln(w, c.Fingerprint); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, err := io.WriteString(w, r.Fingerprint)
+	return err
+}
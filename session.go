@@ -0,0 +1,64 @@
+package gochroma
+
+import "github.com/go-fingerprint/gochroma/chromaprint"
+
+// A Session is a single fingerprinting pass fed by repeated calls to
+// Write, for callers that receive PCM data in arbitrarily sized pieces
+// (e.g. a live capture or an HTTP request body) rather than from a
+// single io.Reader. Only one Session per Printer may be active at a
+// time.
+type Session struct {
+	context        *chromaprint.ChromaprintContext
+	rate, channels int
+	written        int
+}
+
+// Session creates a new Session backed by p's algorithm.
+func (p *Printer) Session() *Session {
+	return &Session{context: p.context}
+}
+
+// Start begins a new fingerprinting session for audio at the given
+// sample rate and channel count. It must be called before Write.
+func (s *Session) Start(rate, channels int) error {
+	if err := s.context.Start(rate, channels); err != nil {
+		return err
+	}
+	s.rate, s.channels = rate, channels
+	s.written = 0
+	return nil
+}
+
+// Write feeds pcm, a buffer of 16-bit signed little-endian samples, into
+// the session. It satisfies io.Writer.
+func (s *Session) Write(pcm []byte) (int, error) {
+	if err := s.context.Feed(pcm); err != nil {
+		return 0, err
+	}
+	s.written += len(pcm)
+	return len(pcm), nil
+}
+
+// Close finishes the session, calculates the fingerprint, and returns it
+// as a Result.
+func (s *Session) Close() (Result, error) {
+	if err := s.context.Finish(); err != nil {
+		return Result{}, err
+	}
+	fprint, err := s.context.GetFingerprint()
+	if err != nil {
+		return Result{}, err
+	}
+	raw, err := s.context.GetRawFingerprint()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Duration:       bytesToDuration(s.written, s.rate, 2*s.channels).Seconds(),
+		Fingerprint:    fprint,
+		RawFingerprint: raw,
+		Algorithm:      s.context.Algorithm(),
+		SampleRate:     s.rate,
+		Channels:       s.channels,
+	}, nil
+}